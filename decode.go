@@ -0,0 +1,399 @@
+package umpparser
+
+import (
+	"sync"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// partDecoders holds user-registered decoders for UMP part types with no
+// built-in handling in stateMachineParser, keyed by PartType. Populated via
+// RegisterPartDecoder.
+var (
+	partDecodersMu sync.RWMutex
+	partDecoders   = map[PartType]func([]byte) (any, error){}
+)
+
+// RegisterPartDecoder installs fn as the decoder for partType, so that
+// future calls to ParseUMPChunks/ParseUMPFull store its result in
+// UMPData.Extra[PartType(partType)] instead of leaving the raw payload in
+// UMPData.Unknown. This lets downstream users decode part types this
+// package does not yet model without forking it. Registering a type that
+// already has built-in handling (MEDIA_HEADER, MEDIA, MEDIA_END,
+// LIVE_METADATA, NEXT_REQUEST_POLICY, FORMAT_INITIALIZATION_METADATA,
+// SABR_REDIRECT, SABR_ERROR, or STREAM_PROTECTION_STATUS) has no effect,
+// since those are always decoded into their dedicated UMPData fields.
+func RegisterPartDecoder(partType int, fn func([]byte) (any, error)) {
+	partDecodersMu.Lock()
+	defer partDecodersMu.Unlock()
+	partDecoders[PartType(partType)] = fn
+}
+
+func lookupPartDecoder(t PartType) (func([]byte) (any, error), bool) {
+	partDecodersMu.RLock()
+	defer partDecodersMu.RUnlock()
+	fn, ok := partDecoders[t]
+	return fn, ok
+}
+
+// consumeUnknownField skips over a protobuf field the caller's decoder does
+// not model, returning the number of bytes consumed.
+func consumeUnknownField(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+	n := protowire.ConsumeFieldValue(num, typ, b)
+	if n < 0 {
+		return 0, protowire.ParseError(n)
+	}
+	return n, nil
+}
+
+// decodeMediaEnd decodes a MEDIA_END (22) payload, sent once all media
+// bytes for the header it references have been delivered.
+func decodeMediaEnd(payload []byte) (*UMPMediaEnd, error) {
+	out := &UMPMediaEnd{}
+	for len(payload) > 0 {
+		num, typ, n := protowire.ConsumeTag(payload)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		payload = payload[n:]
+		switch {
+		case num == 1 && typ == protowire.VarintType:
+			v, n := protowire.ConsumeVarint(payload)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			out.HeaderID = int32(v)
+			payload = payload[n:]
+		default:
+			n, err := consumeUnknownField(num, typ, payload)
+			if err != nil {
+				return nil, err
+			}
+			payload = payload[n:]
+		}
+	}
+	return out, nil
+}
+
+// decodeLiveMetadata decodes a LIVE_METADATA (31) payload.
+func decodeLiveMetadata(payload []byte) (*UMPLiveMetadata, error) {
+	out := &UMPLiveMetadata{}
+	for len(payload) > 0 {
+		num, typ, n := protowire.ConsumeTag(payload)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		payload = payload[n:]
+		switch {
+		case num == 1 && typ == protowire.VarintType:
+			v, n := protowire.ConsumeVarint(payload)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			out.HeadSequenceNumber = int32(v)
+			payload = payload[n:]
+		case num == 7 && typ == protowire.VarintType:
+			v, n := protowire.ConsumeVarint(payload)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			out.WallClockSeconds = int64(v)
+			payload = payload[n:]
+		default:
+			n, err := consumeUnknownField(num, typ, payload)
+			if err != nil {
+				return nil, err
+			}
+			payload = payload[n:]
+		}
+	}
+	return out, nil
+}
+
+// decodeNextRequestPolicy decodes a NEXT_REQUEST_POLICY (35) payload, which
+// carries hints the client must echo on the next request in a segmented or
+// livestream playback session.
+func decodeNextRequestPolicy(payload []byte) (*UMPNextRequestPolicy, error) {
+	out := &UMPNextRequestPolicy{}
+	for len(payload) > 0 {
+		num, typ, n := protowire.ConsumeTag(payload)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		payload = payload[n:]
+		switch {
+		case num == 1 && typ == protowire.VarintType:
+			v, n := protowire.ConsumeVarint(payload)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			out.TargetAudioReadaheadMs = int32(v)
+			payload = payload[n:]
+		case num == 2 && typ == protowire.VarintType:
+			v, n := protowire.ConsumeVarint(payload)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			out.TargetVideoReadaheadMs = int32(v)
+			payload = payload[n:]
+		case num == 3 && typ == protowire.VarintType:
+			v, n := protowire.ConsumeVarint(payload)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			out.BackoffTimeMs = int32(v)
+			payload = payload[n:]
+		case num == 4 && typ == protowire.BytesType:
+			v, n := protowire.ConsumeBytes(payload)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			out.PlaybackCookie = append([]byte(nil), v...)
+			payload = payload[n:]
+		default:
+			n, err := consumeUnknownField(num, typ, payload)
+			if err != nil {
+				return nil, err
+			}
+			payload = payload[n:]
+		}
+	}
+	return out, nil
+}
+
+// decodeFormatInitializationMetadata decodes a
+// FORMAT_INITIALIZATION_METADATA (42) payload, which describes the
+// initialization segment layout for a selected format.
+func decodeFormatInitializationMetadata(payload []byte) (*UMPFormatInitializationMetadata, error) {
+	out := &UMPFormatInitializationMetadata{}
+	for len(payload) > 0 {
+		num, typ, n := protowire.ConsumeTag(payload)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		payload = payload[n:]
+		switch {
+		case num == 1 && typ == protowire.BytesType:
+			v, n := protowire.ConsumeBytes(payload)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			out.VideoID = string(v)
+			payload = payload[n:]
+		case num == 2 && typ == protowire.VarintType:
+			v, n := protowire.ConsumeVarint(payload)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			out.ITag = int32(v)
+			payload = payload[n:]
+		default:
+			n, err := consumeUnknownField(num, typ, payload)
+			if err != nil {
+				return nil, err
+			}
+			payload = payload[n:]
+		}
+	}
+	return out, nil
+}
+
+// decodeSabrRedirect decodes a SABR_REDIRECT (43) payload, instructing the
+// client to re-issue its request against a different SABR endpoint.
+func decodeSabrRedirect(payload []byte) (*UMPSabrRedirect, error) {
+	out := &UMPSabrRedirect{}
+	for len(payload) > 0 {
+		num, typ, n := protowire.ConsumeTag(payload)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		payload = payload[n:]
+		switch {
+		case num == 1 && typ == protowire.BytesType:
+			v, n := protowire.ConsumeBytes(payload)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			out.URL = string(v)
+			payload = payload[n:]
+		default:
+			n, err := consumeUnknownField(num, typ, payload)
+			if err != nil {
+				return nil, err
+			}
+			payload = payload[n:]
+		}
+	}
+	return out, nil
+}
+
+// decodeSabrError decodes a SABR_ERROR (44) payload, a fatal error reported
+// by the SABR endpoint that should abort playback.
+func decodeSabrError(payload []byte) (*UMPSabrError, error) {
+	out := &UMPSabrError{}
+	for len(payload) > 0 {
+		num, typ, n := protowire.ConsumeTag(payload)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		payload = payload[n:]
+		switch {
+		case num == 1 && typ == protowire.VarintType:
+			v, n := protowire.ConsumeVarint(payload)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			out.Code = int32(v)
+			payload = payload[n:]
+		case num == 2 && typ == protowire.BytesType:
+			v, n := protowire.ConsumeBytes(payload)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			out.Type = string(v)
+			payload = payload[n:]
+		default:
+			n, err := consumeUnknownField(num, typ, payload)
+			if err != nil {
+				return nil, err
+			}
+			payload = payload[n:]
+		}
+	}
+	return out, nil
+}
+
+// encodeMediaEnd encodes a UMPMediaEnd back into its MEDIA_END (22) wire
+// payload, the inverse of decodeMediaEnd.
+func encodeMediaEnd(v *UMPMediaEnd) []byte {
+	var b []byte
+	if v.HeaderID != 0 {
+		b = protowire.AppendTag(b, 1, protowire.VarintType)
+		b = protowire.AppendVarint(b, uint64(v.HeaderID))
+	}
+	return b
+}
+
+// encodeLiveMetadata encodes a UMPLiveMetadata back into its LIVE_METADATA
+// (31) wire payload, the inverse of decodeLiveMetadata.
+func encodeLiveMetadata(v *UMPLiveMetadata) []byte {
+	var b []byte
+	if v.HeadSequenceNumber != 0 {
+		b = protowire.AppendTag(b, 1, protowire.VarintType)
+		b = protowire.AppendVarint(b, uint64(v.HeadSequenceNumber))
+	}
+	if v.WallClockSeconds != 0 {
+		b = protowire.AppendTag(b, 7, protowire.VarintType)
+		b = protowire.AppendVarint(b, uint64(v.WallClockSeconds))
+	}
+	return b
+}
+
+// encodeNextRequestPolicy encodes a UMPNextRequestPolicy back into its
+// NEXT_REQUEST_POLICY (35) wire payload, the inverse of
+// decodeNextRequestPolicy.
+func encodeNextRequestPolicy(v *UMPNextRequestPolicy) []byte {
+	var b []byte
+	if v.TargetAudioReadaheadMs != 0 {
+		b = protowire.AppendTag(b, 1, protowire.VarintType)
+		b = protowire.AppendVarint(b, uint64(v.TargetAudioReadaheadMs))
+	}
+	if v.TargetVideoReadaheadMs != 0 {
+		b = protowire.AppendTag(b, 2, protowire.VarintType)
+		b = protowire.AppendVarint(b, uint64(v.TargetVideoReadaheadMs))
+	}
+	if v.BackoffTimeMs != 0 {
+		b = protowire.AppendTag(b, 3, protowire.VarintType)
+		b = protowire.AppendVarint(b, uint64(v.BackoffTimeMs))
+	}
+	if len(v.PlaybackCookie) > 0 {
+		b = protowire.AppendTag(b, 4, protowire.BytesType)
+		b = protowire.AppendBytes(b, v.PlaybackCookie)
+	}
+	return b
+}
+
+// encodeFormatInitializationMetadata encodes a
+// UMPFormatInitializationMetadata back into its
+// FORMAT_INITIALIZATION_METADATA (42) wire payload, the inverse of
+// decodeFormatInitializationMetadata.
+func encodeFormatInitializationMetadata(v *UMPFormatInitializationMetadata) []byte {
+	var b []byte
+	if v.VideoID != "" {
+		b = protowire.AppendTag(b, 1, protowire.BytesType)
+		b = protowire.AppendString(b, v.VideoID)
+	}
+	if v.ITag != 0 {
+		b = protowire.AppendTag(b, 2, protowire.VarintType)
+		b = protowire.AppendVarint(b, uint64(v.ITag))
+	}
+	return b
+}
+
+// encodeSabrRedirect encodes a UMPSabrRedirect back into its SABR_REDIRECT
+// (43) wire payload, the inverse of decodeSabrRedirect.
+func encodeSabrRedirect(v *UMPSabrRedirect) []byte {
+	var b []byte
+	if v.URL != "" {
+		b = protowire.AppendTag(b, 1, protowire.BytesType)
+		b = protowire.AppendString(b, v.URL)
+	}
+	return b
+}
+
+// encodeSabrError encodes a UMPSabrError back into its SABR_ERROR (44) wire
+// payload, the inverse of decodeSabrError.
+func encodeSabrError(v *UMPSabrError) []byte {
+	var b []byte
+	if v.Code != 0 {
+		b = protowire.AppendTag(b, 1, protowire.VarintType)
+		b = protowire.AppendVarint(b, uint64(v.Code))
+	}
+	if v.Type != "" {
+		b = protowire.AppendTag(b, 2, protowire.BytesType)
+		b = protowire.AppendString(b, v.Type)
+	}
+	return b
+}
+
+// encodeStreamProtectionStatus encodes a UMPStreamProtectionStatus back
+// into its STREAM_PROTECTION_STATUS (57) wire payload, the inverse of
+// decodeStreamProtectionStatus.
+func encodeStreamProtectionStatus(v *UMPStreamProtectionStatus) []byte {
+	var b []byte
+	if v.Status != 0 {
+		b = protowire.AppendTag(b, 1, protowire.VarintType)
+		b = protowire.AppendVarint(b, uint64(v.Status))
+	}
+	return b
+}
+
+// decodeStreamProtectionStatus decodes a STREAM_PROTECTION_STATUS (57)
+// payload, the server's current DRM/playback-protection verdict for the
+// stream.
+func decodeStreamProtectionStatus(payload []byte) (*UMPStreamProtectionStatus, error) {
+	out := &UMPStreamProtectionStatus{}
+	for len(payload) > 0 {
+		num, typ, n := protowire.ConsumeTag(payload)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		payload = payload[n:]
+		switch {
+		case num == 1 && typ == protowire.VarintType:
+			v, n := protowire.ConsumeVarint(payload)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			out.Status = int32(v)
+			payload = payload[n:]
+		default:
+			n, err := consumeUnknownField(num, typ, payload)
+			if err != nil {
+				return nil, err
+			}
+			payload = payload[n:]
+		}
+	}
+	return out, nil
+}
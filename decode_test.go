@@ -0,0 +1,92 @@
+package umpparser
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func TestRegisterPartDecoderPopulatesExtra(t *testing.T) {
+	const testPartType = 123
+	RegisterPartDecoder(testPartType, func(payload []byte) (any, error) {
+		return string(payload), nil
+	})
+
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	if err := w.WritePart(testPartType, []byte("custom payload")); err != nil {
+		t.Fatalf("WritePart: %v", err)
+	}
+
+	result, err := ParseUMPFull(buf.Bytes())
+	if err != nil {
+		t.Fatalf("ParseUMPFull: %v", err)
+	}
+
+	got, ok := result.Extra[PartType(testPartType)]
+	if !ok {
+		t.Fatalf("Extra[%d] not populated", testPartType)
+	}
+	if got != "custom payload" {
+		t.Errorf("Extra[%d] = %v, want %q", testPartType, got, "custom payload")
+	}
+	if _, ok := result.Unknown[PartType(testPartType)]; ok {
+		t.Errorf("Unknown[%d] populated even though a decoder was registered", testPartType)
+	}
+}
+
+func TestRegisterPartDecoderErrorPropagates(t *testing.T) {
+	const testPartType = 124
+	wantErr := fmt.Errorf("boom")
+	RegisterPartDecoder(testPartType, func(payload []byte) (any, error) {
+		return nil, wantErr
+	})
+
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	if err := w.WritePart(testPartType, []byte("x")); err != nil {
+		t.Fatalf("WritePart: %v", err)
+	}
+
+	if _, err := ParseUMPFull(buf.Bytes()); err == nil {
+		t.Fatal("ParseUMPFull: expected error from registered decoder, got nil")
+	}
+}
+
+func TestUnregisteredPartTypeFallsBackToUnknown(t *testing.T) {
+	const testPartType = 125
+
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	if err := w.WritePart(testPartType, []byte("raw")); err != nil {
+		t.Fatalf("WritePart: %v", err)
+	}
+
+	result, err := ParseUMPFull(buf.Bytes())
+	if err != nil {
+		t.Fatalf("ParseUMPFull: %v", err)
+	}
+	payloads, ok := result.Unknown[PartType(testPartType)]
+	if !ok || len(payloads) != 1 || string(payloads[0]) != "raw" {
+		t.Errorf("Unknown[%d] = %v, want [%q]", testPartType, payloads, "raw")
+	}
+}
+
+func TestDecodeEncodeNextRequestPolicyRoundTrip(t *testing.T) {
+	want := &UMPNextRequestPolicy{
+		TargetAudioReadaheadMs: 1500,
+		TargetVideoReadaheadMs: 3000,
+		BackoffTimeMs:          250,
+		PlaybackCookie:         []byte{0xDE, 0xAD, 0xBE, 0xEF},
+	}
+	got, err := decodeNextRequestPolicy(encodeNextRequestPolicy(want))
+	if err != nil {
+		t.Fatalf("decodeNextRequestPolicy: %v", err)
+	}
+	if got.TargetAudioReadaheadMs != want.TargetAudioReadaheadMs ||
+		got.TargetVideoReadaheadMs != want.TargetVideoReadaheadMs ||
+		got.BackoffTimeMs != want.BackoffTimeMs ||
+		!bytes.Equal(got.PlaybackCookie, want.PlaybackCookie) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
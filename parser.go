@@ -72,17 +72,24 @@ func readVarInt(data []byte, offset *int) (int, error) {
 // stateMachineParser processes the accumulated buffer using the UMP state machine.
 // It follows these steps:
 //  1. While there is data, attempt to read a complete part header (type and payload length).
-//  2. If there isn’t enough data for the header or the full payload, break (to wait for more data).
-//  3. Otherwise, extract the payload and process the part.
+//  2. If there isn’t enough data for the header or the full payload, stop (to wait for more data).
+//  3. Otherwise, extract the payload and process the part, merging it into result.
 //     For media parts (type 21), if this is the very first media block, skip an initial null byte.
-//  4. Remove the consumed bytes from the buffer and repeat.
-func stateMachineParser(buf *bytes.Buffer, mediaAcc *bytes.Buffer, firstMedia *bool) (*UMPMediaHeader, error) {
+//  4. Advance the commit point past the part just processed and repeat.
+//
+// Only bytes through the last fully-parsed part are ever removed from buf:
+// a part header that is itself split across two calls (e.g. the partType
+// varint lands in one Write and the partSize varint in the next) must not
+// be dropped, or the stray tail bytes get reinterpreted as a new header on
+// the following call.
+func stateMachineParser(buf *bytes.Buffer, mediaAcc *bytes.Buffer, firstMedia *bool, result *UMPData) error {
 	data := buf.Bytes()
 	offset := 0
-	var umpHeader *UMPMediaHeader
+	committed := 0
 	for {
-		// Step 1: If no data remains, break.
+		// Step 1: If no data remains, stop.
 		if offset >= len(data) {
+			committed = offset
 			break
 		}
 		// Step 2: Read part type.
@@ -91,42 +98,46 @@ func stateMachineParser(buf *bytes.Buffer, mediaAcc *bytes.Buffer, firstMedia *b
 			// Not enough data for a complete header.
 			break
 		}
-		// Read part payload length.
-		partSize, err := readVarInt(data, &offset)
+		// Read part payload length as an unsigned 64-bit varint so the full
+		// 5-byte range is representable, then narrow to int with an
+		// explicit overflow check rather than silently truncating.
+		partSize64, err := readVarUint64(data, &offset)
 		if err != nil {
-			// Incomplete header; reset offset to beginning of this part.
-			offset -= 0 // (do nothing here)
+			// Incomplete header (only the partType varint landed so far);
+			// leave committed where it was so these bytes are reread as a
+			// whole header once the rest of partSize arrives.
 			break
 		}
+		if partSize64 > uint64(int(^uint(0)>>1)) {
+			return fmt.Errorf("part size %d exceeds maximum representable int", partSize64)
+		}
+		partSize := int(partSize64)
 		// Step 3: If part size is zero, it's a zero-length part.
 		if partSize == 0 {
-			// Nothing to do; continue with the next part.
+			// Nothing to do, but the header itself is fully parsed.
+			committed = offset
 			continue
 		}
 		// Step 4: Check if we have enough bytes remaining for the payload.
 		if offset+partSize > len(data) {
-			// Incomplete part; break out and wait for more data.
+			// Incomplete part; stop and wait for more data.
 			break
 		}
 		// We have a complete part.
 		payload := data[offset : offset+partSize]
 		// Process the part based on its type.
-		// (The spec says that current type numbers are below 128.)
-		if partType == 20 {
-			// Media header part.
+		switch PartType(partType) {
+		case PartMediaHeader:
 			var protoHeader MediaHeader
 			if err := proto.Unmarshal(payload, &protoHeader); err != nil {
-				return nil, fmt.Errorf("failed to unmarshal media header: %w", err)
+				return fmt.Errorf("failed to unmarshal media header: %w", err)
 			}
-			// Create the UMPMediaHeader struct from the proto message.
-			umpHeader = &UMPMediaHeader{
+			result.MediaHeader = &UMPMediaHeader{
 				VideoID: protoHeader.GetVideoId(),
 				ITag:    protoHeader.GetItag(),
 				Lmt:     protoHeader.GetLmt(),
 			}
-		}
-		if partType == 21 {
-			// Media part.
+		case PartMedia:
 			if !*firstMedia && len(payload) > 0 && payload[0] == 0 {
 				// For the very first media part, skip the leading null byte.
 				mediaAcc.Write(payload[1:])
@@ -134,14 +145,75 @@ func stateMachineParser(buf *bytes.Buffer, mediaAcc *bytes.Buffer, firstMedia *b
 				mediaAcc.Write(payload)
 			}
 			*firstMedia = true
+		case PartMediaEnd:
+			v, err := decodeMediaEnd(payload)
+			if err != nil {
+				return fmt.Errorf("failed to unmarshal media end: %w", err)
+			}
+			result.MediaEnd = v
+		case PartLiveMetadata:
+			v, err := decodeLiveMetadata(payload)
+			if err != nil {
+				return fmt.Errorf("failed to unmarshal live metadata: %w", err)
+			}
+			result.LiveMetadata = v
+		case PartNextRequestPolicy:
+			v, err := decodeNextRequestPolicy(payload)
+			if err != nil {
+				return fmt.Errorf("failed to unmarshal next request policy: %w", err)
+			}
+			result.Policy = v
+		case PartFormatInitializationMetadata:
+			v, err := decodeFormatInitializationMetadata(payload)
+			if err != nil {
+				return fmt.Errorf("failed to unmarshal format initialization metadata: %w", err)
+			}
+			result.FormatInitMetadata = v
+		case PartSabrRedirect:
+			v, err := decodeSabrRedirect(payload)
+			if err != nil {
+				return fmt.Errorf("failed to unmarshal sabr redirect: %w", err)
+			}
+			result.Redirect = v
+		case PartSabrError:
+			v, err := decodeSabrError(payload)
+			if err != nil {
+				return fmt.Errorf("failed to unmarshal sabr error: %w", err)
+			}
+			result.Error = v
+		case PartStreamProtectionStatus:
+			v, err := decodeStreamProtectionStatus(payload)
+			if err != nil {
+				return fmt.Errorf("failed to unmarshal stream protection status: %w", err)
+			}
+			result.ProtectionStatus = v
+		default:
+			if fn, ok := lookupPartDecoder(PartType(partType)); ok {
+				v, err := fn(payload)
+				if err != nil {
+					return fmt.Errorf("failed to decode part %s: %w", PartType(partType), err)
+				}
+				if result.Extra == nil {
+					result.Extra = make(map[PartType]any)
+				}
+				result.Extra[PartType(partType)] = v
+			} else {
+				if result.Unknown == nil {
+					result.Unknown = make(map[PartType][][]byte)
+				}
+				payloadCopy := append([]byte(nil), payload...)
+				result.Unknown[PartType(partType)] = append(result.Unknown[PartType(partType)], payloadCopy)
+			}
 		}
-		// (You can add handling for Part 22 (MEDIA_END) here if desired.)
-		// Step 5: Increment offset by the payload size.
+		// Step 5: Increment offset by the payload size and commit it: the
+		// part starting at the previous commit point is now fully parsed.
 		offset += partSize
+		committed = offset
 	}
-	// Remove the consumed bytes from the buffer.
-	buf.Next(offset)
-	return umpHeader, nil
+	// Remove only the bytes through the last fully-parsed part; anything
+	// after that (a partial header or payload) stays buffered.
+	buf.Next(committed)
+	return nil
 }
 
 // ParseUMPChunks processes a slice of byte slices (chunks) containing UMP data.
@@ -158,7 +230,7 @@ func ParseUMPChunks(data [][]byte) (UMPData, error) {
 
 	var mediaAcc bytes.Buffer
 	var firstMedia bool
-	var umpHeader *UMPMediaHeader
+	var result UMPData
 	buf := bytes.NewBuffer(nil)
 
 	for _, chunk := range data {
@@ -166,19 +238,13 @@ func ParseUMPChunks(data [][]byte) (UMPData, error) {
 			continue // skip empty chunks
 		}
 		buf.Write(chunk)
-		h, err := stateMachineParser(buf, &mediaAcc, &firstMedia)
-		if err != nil {
+		if err := stateMachineParser(buf, &mediaAcc, &firstMedia, &result); err != nil {
 			return UMPData{}, fmt.Errorf("failed to parse UMP data: %w", err)
 		}
-		if h != nil {
-			umpHeader = h // update the header if we found one
-		}
 	}
 
-	return UMPData{
-		MediaHeader: umpHeader,
-		Media:       mediaAcc.Bytes(),
-	}, nil
+	result.Media = mediaAcc.Bytes()
+	return result, nil
 }
 
 // ParseUMPChunks processes a slice of byte slices (chunks) containing UMP data.
@@ -193,21 +259,21 @@ func ParseUMPFull(data []byte) (UMPData, error) {
 
 	var mediaAcc bytes.Buffer
 	var firstMedia bool
-	var umpHeader *UMPMediaHeader
+	var result UMPData
 	buf := bytes.NewBuffer(data)
 
 	for buf.Len() > 0 {
-		h, err := stateMachineParser(buf, &mediaAcc, &firstMedia)
-		if err != nil {
+		before := buf.Len()
+		if err := stateMachineParser(buf, &mediaAcc, &firstMedia, &result); err != nil {
 			return UMPData{}, fmt.Errorf("failed to parse UMP data: %w", err)
 		}
-		if h != nil {
-			umpHeader = h // update the header if we found one
+		// No more input will arrive, so bytes that stateMachineParser left
+		// buffered can never form a complete part.
+		if buf.Len() == before {
+			return UMPData{}, fmt.Errorf("failed to parse UMP data: %d trailing bytes do not form a complete part", buf.Len())
 		}
 	}
 
-	return UMPData{
-		MediaHeader: umpHeader,
-		Media:       mediaAcc.Bytes(),
-	}, nil
+	result.Media = mediaAcc.Bytes()
+	return result, nil
 }
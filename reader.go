@@ -0,0 +1,142 @@
+package umpparser
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// PartHandler is invoked with the payload of a single UMP part during
+// streaming parsing via Reader.Parse. Returning an error aborts the parse
+// loop and is propagated to the caller.
+type PartHandler func(payload []byte) error
+
+// Reader incrementally parses UMP data from an underlying io.Reader,
+// yielding one part at a time via Next. Unlike ParseUMPChunks/ParseUMPFull,
+// it only ever buffers a single pending (incomplete) part header/payload in
+// memory, so it can be used to process multi-GB streams without OOMing.
+type Reader struct {
+	r          io.Reader
+	buf        bytes.Buffer
+	readBuf    []byte
+	mediaSink  io.Writer
+	firstMedia bool
+}
+
+// NewReader returns a Reader that reads UMP-framed data from r.
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: r, readBuf: make([]byte, 32*1024)}
+}
+
+// SetMediaSink routes part 21 (Media) payloads to w instead of returning
+// them from Next, so callers can pipe media straight into a file, an
+// http.ResponseWriter, or an ffmpeg pipe without buffering it. When a sink
+// is set, Next still reports each media part but with a nil payload.
+func (r *Reader) SetMediaSink(w io.Writer) {
+	r.mediaSink = w
+}
+
+// Next reads and returns the next complete UMP part, pulling more bytes
+// from the underlying io.Reader as needed. It returns io.EOF once the
+// underlying reader is exhausted and no partial part remains buffered.
+func (r *Reader) Next() (partType int, payload []byte, err error) {
+	for {
+		pt, part, ok, terr := r.takePart()
+		if terr != nil {
+			return 0, nil, terr
+		}
+		if ok {
+			if pt == 21 {
+				if !r.firstMedia && len(part) > 0 && part[0] == 0 {
+					part = part[1:]
+				}
+				r.firstMedia = true
+				if r.mediaSink != nil {
+					if _, werr := r.mediaSink.Write(part); werr != nil {
+						return 0, nil, fmt.Errorf("failed to write media payload: %w", werr)
+					}
+					return pt, nil, nil
+				}
+			}
+			return pt, part, nil
+		}
+
+		n, rerr := r.r.Read(r.readBuf)
+		if n > 0 {
+			r.buf.Write(r.readBuf[:n])
+		}
+		if rerr != nil {
+			if n == 0 {
+				if errors.Is(rerr, io.EOF) && r.buf.Len() > 0 {
+					return 0, nil, fmt.Errorf("unexpected EOF with %d bytes pending", r.buf.Len())
+				}
+				return 0, nil, rerr
+			}
+		}
+	}
+}
+
+// takePart attempts to extract a single complete part from the front of the
+// buffer, consuming it on success. ok is false if the buffer does not yet
+// hold a complete part (header or payload still incomplete). Zero-length
+// parts carry no payload and are consumed and skipped. err is non-nil only for a malformed part (e.g. a
+// size that cannot fit in an int), never for merely incomplete data.
+func (r *Reader) takePart() (partType int, payload []byte, ok bool, err error) {
+	data := r.buf.Bytes()
+	offset := 0
+	pt, err := readVarInt(data, &offset)
+	if err != nil {
+		return 0, nil, false, nil
+	}
+	// Read the payload length as an unsigned 64-bit varint, like
+	// stateMachineParser, so a part near the 5-byte form's 32-bit range
+	// doesn't silently truncate on 32-bit platforms.
+	size64, err := readVarUint64(data, &offset)
+	if err != nil {
+		return 0, nil, false, nil
+	}
+	if size64 > uint64(int(^uint(0)>>1)) {
+		return 0, nil, false, fmt.Errorf("part size %d exceeds maximum representable int", size64)
+	}
+	size := int(size64)
+	if size == 0 {
+		r.buf.Next(offset)
+		return r.takePart()
+	}
+	if offset+size > len(data) {
+		return 0, nil, false, nil
+	}
+	part := make([]byte, size)
+	copy(part, data[offset:offset+size])
+	r.buf.Next(offset + size)
+	return pt, part, true, nil
+}
+
+// Parse drives Next in a loop, dispatching each part to the handler
+// registered for its type in handlers. Parts with no registered handler are
+// skipped. Parse returns nil when the underlying reader is exhausted, or an
+// error if ctx is cancelled, the stream is malformed, or a handler fails.
+func (r *Reader) Parse(ctx context.Context, handlers map[int]PartHandler) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		partType, payload, err := r.Next()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		if h, ok := handlers[partType]; ok {
+			if err := h(payload); err != nil {
+				return fmt.Errorf("handler for part %d failed: %w", partType, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package umpparser
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+)
+
+// chunkedReader yields the underlying bytes n at a time, so a Reader driven
+// by it sees arbitrary split points, including mid-header.
+type chunkedReader struct {
+	data []byte
+	n    int
+}
+
+func (c *chunkedReader) Read(p []byte) (int, error) {
+	if len(c.data) == 0 {
+		return 0, io.EOF
+	}
+	n := c.n
+	if n > len(c.data) {
+		n = len(c.data)
+	}
+	if n > len(p) {
+		n = len(p)
+	}
+	copy(p, c.data[:n])
+	c.data = c.data[n:]
+	return n, nil
+}
+
+func TestReaderNextRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	if err := w.WritePart(99, []byte("hello")); err != nil {
+		t.Fatalf("WritePart: %v", err)
+	}
+	if err := w.WritePart(100, []byte("world")); err != nil {
+		t.Fatalf("WritePart: %v", err)
+	}
+
+	r := NewReader(bytes.NewReader(buf.Bytes()))
+	var got []struct {
+		partType int
+		payload  string
+	}
+	for {
+		pt, payload, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Next: %v", err)
+		}
+		got = append(got, struct {
+			partType int
+			payload  string
+		}{pt, string(payload)})
+	}
+
+	want := []string{"hello", "world"}
+	if len(got) != len(want) {
+		t.Fatalf("got %d parts, want %d", len(got), len(want))
+	}
+	for i, w := range want {
+		if got[i].payload != w {
+			t.Errorf("part %d payload = %q, want %q", i, got[i].payload, w)
+		}
+	}
+}
+
+// TestReaderNextSplitHeader checks that a part header split across two
+// underlying Read calls (one byte at a time, in the worst case) is neither
+// corrupted nor dropped, mirroring the bug class fixed in stateMachineParser.
+func TestReaderNextSplitHeader(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	payload := bytes.Repeat([]byte{0xAB}, 1<<15) // forces a multi-byte length varint
+	if err := w.WritePart(77, payload); err != nil {
+		t.Fatalf("WritePart: %v", err)
+	}
+
+	r := NewReader(&chunkedReader{data: buf.Bytes(), n: 1})
+	pt, got, err := r.Next()
+	if err != nil {
+		t.Fatalf("Next: %v", err)
+	}
+	if pt != 77 {
+		t.Errorf("partType = %d, want 77", pt)
+	}
+	if !bytes.Equal(got, payload) {
+		t.Errorf("payload mismatch: got %d bytes, want %d", len(got), len(payload))
+	}
+	if _, _, err := r.Next(); err != io.EOF {
+		t.Errorf("final Next: got err %v, want io.EOF", err)
+	}
+}
+
+func TestReaderParseDispatchesHandlers(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	if err := w.WritePart(99, []byte("hello")); err != nil {
+		t.Fatalf("WritePart: %v", err)
+	}
+
+	var got string
+	r := NewReader(bytes.NewReader(buf.Bytes()))
+	err := r.Parse(context.Background(), map[int]PartHandler{
+		99: func(payload []byte) error {
+			got = string(payload)
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if got != "hello" {
+		t.Errorf("got %q, want %q", got, "hello")
+	}
+}
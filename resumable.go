@@ -0,0 +1,155 @@
+package umpparser
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Parser is a push-based, resumable UMP parser. It is modeled on the
+// buffer-then-flush pattern of resumable HTTP uploads: bytes arrive via
+// Write as they are read from a googlevideo response body, and the
+// parser's State can be serialized and later restored with LoadState so
+// that, after a 5xx or a dropped connection, streaming can resume with a
+// Range request instead of re-parsing bytes already consumed.
+type Parser struct {
+	buf           bytes.Buffer
+	mediaAcc      bytes.Buffer
+	firstMedia    bool
+	result        UMPData
+	mediaSink     io.Writer
+	mediaOffset   int64
+	bytesConsumed int64
+}
+
+// NewParser returns an empty Parser ready to accept UMP bytes via Write.
+func NewParser() *Parser {
+	return &Parser{}
+}
+
+// SetMediaSink routes parsed media (part 21) payloads to w as soon as they
+// are decoded, instead of buffering them in memory for Flush. Callers
+// resuming a download should pass the same destination (e.g. the file or
+// http.ResponseWriter the media is being copied into) after LoadState.
+func (p *Parser) SetMediaSink(w io.Writer) {
+	p.mediaSink = w
+}
+
+// Write feeds data into the parser's pending buffer and parses as many
+// complete parts as are now available, accumulating media and keeping the
+// latest value seen so far for every other part type. It never errors on
+// incomplete data; a partial part is retained until more bytes arrive.
+func (p *Parser) Write(data []byte) (int, error) {
+	p.buf.Write(data)
+	if err := stateMachineParser(&p.buf, &p.mediaAcc, &p.firstMedia, &p.result); err != nil {
+		return 0, err
+	}
+	p.bytesConsumed += int64(len(data))
+	if p.mediaSink != nil && p.mediaAcc.Len() > 0 {
+		n, err := p.mediaSink.Write(p.mediaAcc.Bytes())
+		p.mediaOffset += int64(n)
+		// Only the bytes the sink actually accepted are gone; keep the
+		// unwritten remainder buffered so a partial write (e.g. the 5xx or
+		// dropped connection this Parser exists to survive) doesn't lose
+		// media that a subsequent State()/LoadState() would otherwise be
+		// unable to recover.
+		p.mediaAcc.Next(n)
+		if err != nil {
+			return 0, fmt.Errorf("failed to write media payload: %w", err)
+		}
+	}
+	return len(data), nil
+}
+
+// Flush returns a snapshot of everything parsed so far. It can be called
+// between Write calls to inspect results that are already available, such
+// as a live stream's media header, without waiting for the stream to end.
+// If no media sink was set, Media holds all media bytes accumulated so far;
+// otherwise Media is nil, since those bytes have already been written to
+// the sink.
+func (p *Parser) Flush() (UMPData, error) {
+	result := p.result
+	if p.mediaSink == nil {
+		result.Media = p.mediaAcc.Bytes()
+	}
+	return result, nil
+}
+
+// MediaOffset returns the total number of media (part 21) bytes written to
+// the media sink so far. A caller resuming a download after a dropped
+// connection should seek/truncate its sink destination to this offset
+// before replaying media from LoadState, so previously-written bytes are
+// neither duplicated nor left with a gap. It is always zero when no media
+// sink is set, since Flush returns the accumulated media instead.
+func (p *Parser) MediaOffset() int64 {
+	return p.mediaOffset
+}
+
+// BytesConsumed returns the total number of bytes handed to Write so far.
+// On resuming after a dropped connection, request Range: bytes=N- with N
+// set to this value: LoadState restores any bytes that were buffered but
+// not yet folded into a complete part, so resuming from here neither skips
+// nor duplicates data.
+func (p *Parser) BytesConsumed() int64 {
+	return p.bytesConsumed
+}
+
+// parserState is the serialized form of a Parser's internal state, as
+// produced by State and consumed by LoadState.
+type parserState struct {
+	Pending       []byte  `json:"pending"`
+	PendingMedia  []byte  `json:"pending_media,omitempty"`
+	FirstMedia    bool    `json:"first_media"`
+	MediaOffset   int64   `json:"media_offset"`
+	BytesConsumed int64   `json:"bytes_consumed"`
+	Result        UMPData `json:"result"`
+}
+
+// State serializes the parser's pending buffer, firstMedia flag,
+// accumulated media offset, and the full set of UMP data decoded so far
+// (UMPMediaHeader and every other known or registered part type), so that
+// a caller can persist it and later resume parsing from the same point
+// with LoadState. Media bytes still held in memory are also serialized, so
+// calling State mid-stream never drops them: with no media sink set that is
+// all media decoded so far (Flush does not drain it), and with a sink set it
+// is whatever the sink has not yet accepted. If a decoder registered via
+// RegisterPartDecoder produced a value that is not itself
+// JSON-marshalable, State returns an error.
+func (p *Parser) State() ([]byte, error) {
+	st := parserState{
+		Pending:       append([]byte(nil), p.buf.Bytes()...),
+		PendingMedia:  append([]byte(nil), p.mediaAcc.Bytes()...),
+		FirstMedia:    p.firstMedia,
+		MediaOffset:   p.mediaOffset,
+		BytesConsumed: p.bytesConsumed,
+		Result:        p.result,
+	}
+	data, err := json.Marshal(st)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal parser state: %w", err)
+	}
+	return data, nil
+}
+
+// LoadState restores a Parser from a snapshot previously produced by
+// State, discarding any data the parser currently holds. The media sink,
+// if any, must be set again with SetMediaSink after LoadState. Values in
+// the restored result's Extra map come back as the generic types
+// encoding/json decodes into (string, float64, map[string]any, ...), not
+// the types the registered decoder originally returned.
+func (p *Parser) LoadState(data []byte) error {
+	var st parserState
+	if err := json.Unmarshal(data, &st); err != nil {
+		return fmt.Errorf("failed to unmarshal parser state: %w", err)
+	}
+	p.buf.Reset()
+	p.buf.Write(st.Pending)
+	p.mediaAcc.Reset()
+	p.mediaAcc.Write(st.PendingMedia)
+	p.firstMedia = st.FirstMedia
+	p.mediaOffset = st.MediaOffset
+	p.bytesConsumed = st.BytesConsumed
+	p.result = st.Result
+	return nil
+}
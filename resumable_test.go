@@ -0,0 +1,121 @@
+package umpparser
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestParserWriteSplitAcrossCalls(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	payload := bytes.Repeat([]byte{0xCD}, 1<<15) // forces a multi-byte length varint
+	if err := w.WritePart(int(PartSabrError), encodeSabrError(&UMPSabrError{Code: 9, Type: "X"})); err != nil {
+		t.Fatalf("WritePart: %v", err)
+	}
+	if err := w.WritePart(77, payload); err != nil {
+		t.Fatalf("WritePart: %v", err)
+	}
+
+	p := NewParser()
+	data := buf.Bytes()
+	for i, b := range data {
+		if _, err := p.Write([]byte{b}); err != nil {
+			t.Fatalf("Write byte %d: %v", i, err)
+		}
+	}
+
+	result, err := p.Flush()
+	if err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	if result.Error == nil || result.Error.Code != 9 || result.Error.Type != "X" {
+		t.Errorf("Error = %+v, want {Code:9 Type:X}", result.Error)
+	}
+	if payloads := result.Unknown[77]; len(payloads) != 1 || !bytes.Equal(payloads[0], payload) {
+		t.Errorf("Unknown[77] not reassembled correctly")
+	}
+}
+
+func TestParserStateLoadStateRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	if err := w.WritePart(int(PartSabrError), encodeSabrError(&UMPSabrError{Code: 4, Type: "Y"})); err != nil {
+		t.Fatalf("WritePart: %v", err)
+	}
+	full := buf.Bytes()
+	split := len(full) / 2
+
+	p := NewParser()
+	if _, err := p.Write(full[:split]); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	state, err := p.State()
+	if err != nil {
+		t.Fatalf("State: %v", err)
+	}
+
+	resumed := NewParser()
+	if err := resumed.LoadState(state); err != nil {
+		t.Fatalf("LoadState: %v", err)
+	}
+	if _, err := resumed.Write(full[split:]); err != nil {
+		t.Fatalf("Write after LoadState: %v", err)
+	}
+
+	result, err := resumed.Flush()
+	if err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	if result.Error == nil || result.Error.Code != 4 || result.Error.Type != "Y" {
+		t.Errorf("Error = %+v, want {Code:4 Type:Y}", result.Error)
+	}
+}
+
+func TestParserLoadStateRestoresFullResult(t *testing.T) {
+	p := NewParser()
+	if _, err := p.Write(mustEncodeParts(t,
+		part{int(PartSabrError), encodeSabrError(&UMPSabrError{Code: 1, Type: "A"})},
+		part{int(PartStreamProtectionStatus), encodeStreamProtectionStatus(&UMPStreamProtectionStatus{Status: 2})},
+	)); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	state, err := p.State()
+	if err != nil {
+		t.Fatalf("State: %v", err)
+	}
+
+	resumed := NewParser()
+	if err := resumed.LoadState(state); err != nil {
+		t.Fatalf("LoadState: %v", err)
+	}
+
+	result, err := resumed.Flush()
+	if err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	if result.Error == nil || result.Error.Code != 1 {
+		t.Errorf("Error not restored by LoadState: %+v", result.Error)
+	}
+	if result.ProtectionStatus == nil || result.ProtectionStatus.Status != 2 {
+		t.Errorf("ProtectionStatus not restored by LoadState: %+v", result.ProtectionStatus)
+	}
+}
+
+type part struct {
+	partType int
+	payload  []byte
+}
+
+func mustEncodeParts(t *testing.T, parts ...part) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	for _, p := range parts {
+		if err := w.WritePart(p.partType, p.payload); err != nil {
+			t.Fatalf("WritePart: %v", err)
+		}
+	}
+	return buf.Bytes()
+}
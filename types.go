@@ -1,8 +1,66 @@
 package umpparser
 
+import "fmt"
+
+// PartType identifies the type of a single UMP part, as sent in the
+// varint-encoded header that precedes every part's payload.
+type PartType int
+
+// Known UMP part types. Only a subset of the full range used by the
+// YouTube SABR/UMP protocol is modeled with a dedicated UMPData field;
+// unrecognized types fall back to UMPData.Unknown, or to UMPData.Extra if a
+// decoder for them was installed via RegisterPartDecoder.
+const (
+	PartMediaHeader                  PartType = 20
+	PartMedia                        PartType = 21
+	PartMediaEnd                     PartType = 22
+	PartLiveMetadata                 PartType = 31
+	PartNextRequestPolicy            PartType = 35
+	PartFormatInitializationMetadata PartType = 42
+	PartSabrRedirect                 PartType = 43
+	PartSabrError                    PartType = 44
+	PartStreamProtectionStatus       PartType = 57
+)
+
+// String implements fmt.Stringer, returning the part type's symbolic name
+// or "PartType(N)" for unrecognized values.
+func (t PartType) String() string {
+	switch t {
+	case PartMediaHeader:
+		return "MEDIA_HEADER"
+	case PartMedia:
+		return "MEDIA"
+	case PartMediaEnd:
+		return "MEDIA_END"
+	case PartLiveMetadata:
+		return "LIVE_METADATA"
+	case PartNextRequestPolicy:
+		return "NEXT_REQUEST_POLICY"
+	case PartFormatInitializationMetadata:
+		return "FORMAT_INITIALIZATION_METADATA"
+	case PartSabrRedirect:
+		return "SABR_REDIRECT"
+	case PartSabrError:
+		return "SABR_ERROR"
+	case PartStreamProtectionStatus:
+		return "STREAM_PROTECTION_STATUS"
+	default:
+		return fmt.Sprintf("PartType(%d)", int(t))
+	}
+}
+
 type UMPData struct {
-	MediaHeader *UMPMediaHeader // MediaHeader is part 20 of the UMP data
-	Media       []byte          // Media is the raw media data, part 21 of the UMP data
+	MediaHeader        *UMPMediaHeader                  // MediaHeader is part 20 of the UMP data
+	Media              []byte                           // Media is the raw media data, part 21 of the UMP data
+	MediaEnd           *UMPMediaEnd                     // MediaEnd is part 22 of the UMP data
+	LiveMetadata       *UMPLiveMetadata                 // LiveMetadata is part 31 of the UMP data
+	Policy             *UMPNextRequestPolicy            // Policy is part 35 of the UMP data
+	FormatInitMetadata *UMPFormatInitializationMetadata // FormatInitMetadata is part 42 of the UMP data
+	Redirect           *UMPSabrRedirect                 // Redirect is part 43 of the UMP data
+	Error              *UMPSabrError                    // Error is part 44 of the UMP data
+	ProtectionStatus   *UMPStreamProtectionStatus       // ProtectionStatus is part 57 of the UMP data
+	Extra              map[PartType]any                 // Extra holds results from decoders registered via RegisterPartDecoder
+	Unknown            map[PartType][][]byte            // Unknown holds the raw payloads of part types with no known or registered decoder
 }
 
 // MediaHeader is a struct that represents part 20 of the UMP data
@@ -11,3 +69,52 @@ type UMPMediaHeader struct {
 	ITag    int32  // ITag is the identifier for the video format, field 3
 	Lmt     int64  // Lmt is the last modified time of the video, field 4
 }
+
+// UMPMediaEnd represents part 22 of the UMP data, sent once all media bytes
+// for the header it references have been delivered.
+type UMPMediaEnd struct {
+	HeaderID int32 // HeaderID links this MediaEnd back to the UMPMediaHeader it completes, field 1
+}
+
+// UMPLiveMetadata represents part 31 of the UMP data, periodic livestream
+// head/wall-clock timing information.
+type UMPLiveMetadata struct {
+	HeadSequenceNumber int32 // HeadSequenceNumber is the sequence number of the most recent live segment, field 1
+	WallClockSeconds   int64 // WallClockSeconds is the wall-clock time of that segment, in seconds since epoch, field 7
+}
+
+// UMPNextRequestPolicy represents part 35 of the UMP data, carrying hints
+// the client must echo on its next request in a segmented or livestream
+// playback session.
+type UMPNextRequestPolicy struct {
+	TargetAudioReadaheadMs int32  // TargetAudioReadaheadMs is the desired audio buffer depth, field 1
+	TargetVideoReadaheadMs int32  // TargetVideoReadaheadMs is the desired video buffer depth, field 2
+	BackoffTimeMs          int32  // BackoffTimeMs is how long to wait before the next request, field 3
+	PlaybackCookie         []byte // PlaybackCookie is opaque state to echo back verbatim on the next request, field 4
+}
+
+// UMPFormatInitializationMetadata represents part 42 of the UMP data,
+// describing the initialization segment layout for a selected format.
+type UMPFormatInitializationMetadata struct {
+	VideoID string // VideoID is the unique identifier for the video, field 1
+	ITag    int32  // ITag is the identifier for the video format, field 2
+}
+
+// UMPSabrRedirect represents part 43 of the UMP data, instructing the
+// client to re-issue its request against a different SABR endpoint.
+type UMPSabrRedirect struct {
+	URL string // URL is the endpoint the client should redirect subsequent requests to, field 1
+}
+
+// UMPSabrError represents part 44 of the UMP data, a fatal error reported
+// by the SABR endpoint that should abort playback.
+type UMPSabrError struct {
+	Code int32  // Code is the numeric error code, field 1
+	Type string // Type is the symbolic error type, field 2
+}
+
+// UMPStreamProtectionStatus represents part 57 of the UMP data, the
+// server's current DRM/playback-protection verdict for the stream.
+type UMPStreamProtectionStatus struct {
+	Status int32 // Status is the protection status code, field 1
+}
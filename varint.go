@@ -0,0 +1,91 @@
+package umpparser
+
+import (
+	"errors"
+	"fmt"
+)
+
+// readVarUint64 reads a UMP varint from data starting at *offset as an
+// unsigned 64-bit integer, advancing *offset past it. It follows the same
+// spec as readVarInt but never truncates the 5-byte form, which carries a
+// full 32-bit unsigned value that does not always fit in an int on 32-bit
+// platforms.
+func readVarUint64(data []byte, offset *int) (uint64, error) {
+	start := *offset
+	if start >= len(data) {
+		return 0, errors.New("no data available")
+	}
+	first := data[start]
+	size := getVarIntSize(first)
+	if size == 0 {
+		return 0, fmt.Errorf("invalid varint at pos %d: all top 5 bits set", start)
+	}
+	if start+size > len(data) {
+		return 0, errors.New("incomplete varint")
+	}
+	var value uint64
+	switch size {
+	case 1:
+		value = uint64(first)
+	case 2:
+		value = uint64(first&0x3F) | (uint64(data[start+1]) << 6)
+	case 3:
+		value = uint64(first&0x1F) | (uint64(data[start+1]) << 5) | (uint64(data[start+2]) << 13)
+	case 4:
+		value = uint64(first&0x0F) | (uint64(data[start+1]) << 4) | (uint64(data[start+2]) << 12) | (uint64(data[start+3]) << 20)
+	case 5:
+		value = uint64(data[start+1]) | (uint64(data[start+2]) << 8) | (uint64(data[start+3]) << 16) | (uint64(data[start+4]) << 24)
+	}
+	*offset += size
+	return value, nil
+}
+
+// ReadVarInt reads a single UMP varint from data starting at *offset,
+// advancing *offset past it. It is exported so callers can decode raw UMP
+// frames themselves, e.g. when building a custom transport, without
+// reimplementing this package's varint format.
+func ReadVarInt(data []byte, offset *int) (uint64, error) {
+	return readVarUint64(data, offset)
+}
+
+// ReadVarInt64 reads a single UMP varint from data starting at *offset and
+// zig-zag decodes it into a signed 64-bit integer, mirroring protobuf's
+// sintN encoding. It is exported alongside ReadVarInt for the UMP fields
+// that carry signed values.
+func ReadVarInt64(data []byte, offset *int) (int64, error) {
+	u, err := readVarUint64(data, offset)
+	if err != nil {
+		return 0, err
+	}
+	return int64(u>>1) ^ -int64(u&1), nil
+}
+
+// WriteVarInt appends the UMP varint encoding of value to dst and returns
+// the extended slice. It is the inverse of ReadVarInt, letting callers
+// encode UMP frames, e.g. to build test fixtures or a mock server. value
+// must fit in the format's 32-bit unsigned range.
+func WriteVarInt(dst []byte, value uint64) ([]byte, error) {
+	switch {
+	case value < 1<<7:
+		return append(dst, byte(value)), nil
+	case value < 1<<14:
+		return append(dst, 0x80|byte(value&0x3F), byte(value>>6)), nil
+	case value < 1<<21:
+		return append(dst, 0xC0|byte(value&0x1F), byte(value>>5), byte(value>>13)), nil
+	case value < 1<<28:
+		return append(dst, 0xE0|byte(value&0x0F), byte(value>>4), byte(value>>12), byte(value>>20)), nil
+	case value <= 0xFFFFFFFF:
+		return append(dst, 0xF0, byte(value), byte(value>>8), byte(value>>16), byte(value>>24)), nil
+	default:
+		return nil, fmt.Errorf("value %d exceeds maximum UMP varint range (2^32-1)", value)
+	}
+}
+
+// WriteVarInt64 zig-zag encodes value and appends its UMP varint encoding
+// to dst, returning the extended slice. It is the inverse of ReadVarInt64.
+// value must zig-zag encode to within the format's 32-bit unsigned range,
+// i.e. fit in an int32.
+func WriteVarInt64(dst []byte, value int64) ([]byte, error) {
+	zigzag := uint64((value << 1) ^ (value >> 63))
+	return WriteVarInt(dst, zigzag)
+}
@@ -0,0 +1,126 @@
+package umpparser
+
+import "testing"
+
+func TestReadVarUint64SizeClasses(t *testing.T) {
+	tests := []struct {
+		name  string
+		value uint64
+	}{
+		{"1-byte min", 0},
+		{"1-byte max", 1<<7 - 1},
+		{"2-byte min", 1 << 7},
+		{"2-byte max", 1<<14 - 1},
+		{"3-byte min", 1 << 14},
+		{"3-byte max", 1<<21 - 1},
+		{"4-byte min", 1 << 21},
+		{"4-byte max", 1<<28 - 1},
+		{"5-byte min", 1 << 28},
+		{"5-byte max", 0xFFFFFFFF},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded, err := WriteVarInt(nil, tt.value)
+			if err != nil {
+				t.Fatalf("WriteVarInt(%d): %v", tt.value, err)
+			}
+			offset := 0
+			got, err := readVarUint64(encoded, &offset)
+			if err != nil {
+				t.Fatalf("readVarUint64(% x): %v", encoded, err)
+			}
+			if got != tt.value {
+				t.Errorf("got %d, want %d", got, tt.value)
+			}
+			if offset != len(encoded) {
+				t.Errorf("offset = %d, want %d (whole encoding consumed)", offset, len(encoded))
+			}
+		})
+	}
+}
+
+func TestReadVarUint64InvalidAllTopBitsSet(t *testing.T) {
+	// getVarIntSize treats a first byte with all five top bits set
+	// (0x80|0x40|0x20|0x10|0x08) as an invalid varint; the lower three bits
+	// are unconstrained.
+	for _, first := range []byte{0xF8, 0xFB, 0xFF} {
+		data := []byte{first, 0x00, 0x00, 0x00, 0x00}
+		offset := 0
+		if _, err := readVarUint64(data, &offset); err == nil {
+			t.Errorf("readVarUint64 with first byte %#x: expected error, got nil", first)
+		}
+	}
+}
+
+func TestReadVarIntMatchesReadVarUint64(t *testing.T) {
+	for _, value := range []uint64{0, 1<<7 - 1, 1 << 7, 1<<28 - 1, 0xFFFFFFFF} {
+		encoded, err := WriteVarInt(nil, value)
+		if err != nil {
+			t.Fatalf("WriteVarInt(%d): %v", value, err)
+		}
+		offset := 0
+		got, err := ReadVarInt(encoded, &offset)
+		if err != nil {
+			t.Fatalf("ReadVarInt(% x): %v", encoded, err)
+		}
+		if got != value {
+			t.Errorf("got %d, want %d", got, value)
+		}
+	}
+}
+
+// FuzzReadVarInt checks that decoding never panics or advances the offset
+// out of bounds, regardless of how malformed the input is.
+func FuzzReadVarInt(f *testing.F) {
+	f.Add([]byte{0x00})
+	f.Add([]byte{0x80, 0x01})
+	f.Add([]byte{0xC0, 0x01, 0x00})
+	f.Add([]byte{0xF8})
+	f.Add([]byte{0xF0, 0xFF, 0xFF, 0xFF, 0xFF})
+	f.Fuzz(func(t *testing.T, data []byte) {
+		offset := 0
+		if _, err := ReadVarInt(data, &offset); err != nil {
+			return
+		}
+		if offset < 0 || offset > len(data) {
+			t.Fatalf("offset %d out of bounds for input of length %d", offset, len(data))
+		}
+	})
+}
+
+// FuzzWriteReadVarIntRoundTrip checks that every value WriteVarInt accepts
+// (the format's 32-bit unsigned range) round-trips through ReadVarInt
+// unchanged, across all five size classes.
+func FuzzWriteReadVarIntRoundTrip(f *testing.F) {
+	f.Add(uint64(0))
+	f.Add(uint64(1<<7 - 1))
+	f.Add(uint64(1 << 7))
+	f.Add(uint64(1<<14 - 1))
+	f.Add(uint64(1 << 14))
+	f.Add(uint64(1 << 21))
+	f.Add(uint64(1<<28 - 1))
+	f.Add(uint64(0xFFFFFFFF))
+	f.Fuzz(func(t *testing.T, value uint64) {
+		encoded, err := WriteVarInt(nil, value)
+		if value > 0xFFFFFFFF {
+			if err == nil {
+				t.Fatalf("WriteVarInt(%d): expected an out-of-range error, got nil", value)
+			}
+			return
+		}
+		if err != nil {
+			t.Fatalf("WriteVarInt(%d): %v", value, err)
+		}
+		offset := 0
+		got, err := ReadVarInt(encoded, &offset)
+		if err != nil {
+			t.Fatalf("ReadVarInt(% x): %v", encoded, err)
+		}
+		if got != value {
+			t.Fatalf("got %d, want %d", got, value)
+		}
+		if offset != len(encoded) {
+			t.Fatalf("offset = %d, want %d", offset, len(encoded))
+		}
+	})
+}
@@ -0,0 +1,153 @@
+package umpparser
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// Writer encodes UMP parts to an underlying io.Writer, emitting the same
+// varint-prefixed frames that Reader and ParseUMPFull/ParseUMPChunks
+// consume. It is the symmetric counterpart to this package's decoders,
+// useful for building a SABR proxy, a mock server, or fuzz/test fixtures.
+type Writer struct {
+	w          io.Writer
+	firstMedia bool
+}
+
+// NewWriter returns a Writer that emits UMP-framed data to w.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w}
+}
+
+// WritePart writes a single UMP part: partType and len(payload) as
+// varints, followed by payload itself.
+func (w *Writer) WritePart(partType int, payload []byte) error {
+	if partType < 0 {
+		return fmt.Errorf("part type %d must not be negative", partType)
+	}
+	header, err := WriteVarInt(nil, uint64(partType))
+	if err != nil {
+		return fmt.Errorf("failed to encode part type %d: %w", partType, err)
+	}
+	header, err = WriteVarInt(header, uint64(len(payload)))
+	if err != nil {
+		return fmt.Errorf("failed to encode length of part %d: %w", partType, err)
+	}
+	if _, err := w.w.Write(header); err != nil {
+		return fmt.Errorf("failed to write part %d header: %w", partType, err)
+	}
+	if len(payload) > 0 {
+		if _, err := w.w.Write(payload); err != nil {
+			return fmt.Errorf("failed to write part %d payload: %w", partType, err)
+		}
+	}
+	return nil
+}
+
+// WriteMediaHeader writes h as a MEDIA_HEADER (20) part.
+func (w *Writer) WriteMediaHeader(h *UMPMediaHeader) error {
+	protoHeader := MediaHeader{
+		VideoId: h.VideoID,
+		Itag:    h.ITag,
+		Lmt:     h.Lmt,
+	}
+	payload, err := proto.Marshal(&protoHeader)
+	if err != nil {
+		return fmt.Errorf("failed to marshal media header: %w", err)
+	}
+	return w.WritePart(int(PartMediaHeader), payload)
+}
+
+// WriteMedia writes data as a MEDIA (21) part. As with the parts this
+// package decodes, the very first media part written by a Writer is
+// prefixed with a null byte, matching the on-the-wire behavior Reader and
+// stateMachineParser expect.
+func (w *Writer) WriteMedia(data []byte) error {
+	payload := data
+	if !w.firstMedia {
+		payload = make([]byte, 0, len(data)+1)
+		payload = append(payload, 0)
+		payload = append(payload, data...)
+	}
+	w.firstMedia = true
+	return w.WritePart(int(PartMedia), payload)
+}
+
+// MarshalUMP encodes data back into UMP-framed bytes, the inverse of
+// ParseUMPFull. Only the fields ParseUMPFull/ParseUMPChunks populate
+// without a custom decoder are re-encoded: Extra is not re-encoded, since
+// there is no generic inverse for a user-registered decoder, but Unknown
+// payloads are written back out verbatim, sorted by part type, so that
+// encoding the same UMPData twice (e.g. in a mock server or fuzz harness)
+// always produces byte-identical output. A part whose payload encodes to
+// zero bytes (e.g. a UMPMediaEnd with HeaderID 0, or an empty Unknown
+// payload) is still written, but ParseUMPFull skips zero-length parts, so it
+// does not come back from a round trip.
+func MarshalUMP(data UMPData) ([]byte, error) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+
+	if data.MediaHeader != nil {
+		if err := w.WriteMediaHeader(data.MediaHeader); err != nil {
+			return nil, err
+		}
+	}
+	if len(data.Media) > 0 {
+		if err := w.WriteMedia(data.Media); err != nil {
+			return nil, err
+		}
+	}
+	if data.MediaEnd != nil {
+		if err := w.WritePart(int(PartMediaEnd), encodeMediaEnd(data.MediaEnd)); err != nil {
+			return nil, err
+		}
+	}
+	if data.LiveMetadata != nil {
+		if err := w.WritePart(int(PartLiveMetadata), encodeLiveMetadata(data.LiveMetadata)); err != nil {
+			return nil, err
+		}
+	}
+	if data.Policy != nil {
+		if err := w.WritePart(int(PartNextRequestPolicy), encodeNextRequestPolicy(data.Policy)); err != nil {
+			return nil, err
+		}
+	}
+	if data.FormatInitMetadata != nil {
+		if err := w.WritePart(int(PartFormatInitializationMetadata), encodeFormatInitializationMetadata(data.FormatInitMetadata)); err != nil {
+			return nil, err
+		}
+	}
+	if data.Redirect != nil {
+		if err := w.WritePart(int(PartSabrRedirect), encodeSabrRedirect(data.Redirect)); err != nil {
+			return nil, err
+		}
+	}
+	if data.Error != nil {
+		if err := w.WritePart(int(PartSabrError), encodeSabrError(data.Error)); err != nil {
+			return nil, err
+		}
+	}
+	if data.ProtectionStatus != nil {
+		if err := w.WritePart(int(PartStreamProtectionStatus), encodeStreamProtectionStatus(data.ProtectionStatus)); err != nil {
+			return nil, err
+		}
+	}
+	unknownTypes := make([]PartType, 0, len(data.Unknown))
+	for partType := range data.Unknown {
+		unknownTypes = append(unknownTypes, partType)
+	}
+	sort.Slice(unknownTypes, func(i, j int) bool { return unknownTypes[i] < unknownTypes[j] })
+	for _, partType := range unknownTypes {
+		for _, payload := range data.Unknown[partType] {
+			if err := w.WritePart(int(partType), payload); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,80 @@
+package umpparser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMarshalUMPRoundTrip(t *testing.T) {
+	want := UMPData{
+		MediaEnd:          &UMPMediaEnd{HeaderID: 7},
+		LiveMetadata:      &UMPLiveMetadata{HeadSequenceNumber: 42, WallClockSeconds: 1234567890},
+		Policy:            &UMPNextRequestPolicy{TargetAudioReadaheadMs: 1000, TargetVideoReadaheadMs: 2000, BackoffTimeMs: 500, PlaybackCookie: []byte{0x01, 0x02}},
+		Redirect:          &UMPSabrRedirect{URL: "https://example.com/redirect"},
+		Error:             &UMPSabrError{Code: 3, Type: "AUTHENTICATION_FAILED"},
+		ProtectionStatus:  &UMPStreamProtectionStatus{Status: 1},
+		Unknown: map[PartType][][]byte{
+			99: {[]byte("first"), []byte("second")},
+		},
+	}
+
+	encoded, err := MarshalUMP(want)
+	if err != nil {
+		t.Fatalf("MarshalUMP: %v", err)
+	}
+
+	got, err := ParseUMPFull(encoded)
+	if err != nil {
+		t.Fatalf("ParseUMPFull: %v", err)
+	}
+	got.Media = nil // no media part was encoded; Media is not compared below
+
+	if !reflect.DeepEqual(*got.MediaEnd, *want.MediaEnd) {
+		t.Errorf("MediaEnd = %+v, want %+v", got.MediaEnd, want.MediaEnd)
+	}
+	if !reflect.DeepEqual(*got.LiveMetadata, *want.LiveMetadata) {
+		t.Errorf("LiveMetadata = %+v, want %+v", got.LiveMetadata, want.LiveMetadata)
+	}
+	if !reflect.DeepEqual(*got.Policy, *want.Policy) {
+		t.Errorf("Policy = %+v, want %+v", got.Policy, want.Policy)
+	}
+	if !reflect.DeepEqual(*got.Redirect, *want.Redirect) {
+		t.Errorf("Redirect = %+v, want %+v", got.Redirect, want.Redirect)
+	}
+	if !reflect.DeepEqual(*got.Error, *want.Error) {
+		t.Errorf("Error = %+v, want %+v", got.Error, want.Error)
+	}
+	if !reflect.DeepEqual(*got.ProtectionStatus, *want.ProtectionStatus) {
+		t.Errorf("ProtectionStatus = %+v, want %+v", got.ProtectionStatus, want.ProtectionStatus)
+	}
+	if !reflect.DeepEqual(got.Unknown, want.Unknown) {
+		t.Errorf("Unknown = %+v, want %+v", got.Unknown, want.Unknown)
+	}
+}
+
+// TestMarshalUMPUnknownOrderDeterministic checks that MarshalUMP emits
+// Unknown parts in a fixed order across repeated calls on the same input,
+// rather than following Go's randomized map iteration order.
+func TestMarshalUMPUnknownOrderDeterministic(t *testing.T) {
+	data := UMPData{
+		Unknown: map[PartType][][]byte{
+			90: {[]byte("a")},
+			50: {[]byte("b")},
+			70: {[]byte("c")},
+		},
+	}
+
+	first, err := MarshalUMP(data)
+	if err != nil {
+		t.Fatalf("MarshalUMP: %v", err)
+	}
+	for i := 0; i < 10; i++ {
+		got, err := MarshalUMP(data)
+		if err != nil {
+			t.Fatalf("MarshalUMP: %v", err)
+		}
+		if !reflect.DeepEqual(got, first) {
+			t.Fatalf("MarshalUMP output changed across calls on iteration %d", i)
+		}
+	}
+}